@@ -0,0 +1,115 @@
+package txs
+
+import (
+	"github.com/tendermint/tendermint/crypto"
+)
+
+// PolicyNodeType 背书策略树中节点的类型
+type PolicyNodeType string
+
+const (
+	PolicyAnd PolicyNodeType = "AND" //所有子节点均须满足
+	PolicyOr  PolicyNodeType = "OR"  //至少一个子节点满足
+	PolicyOrg PolicyNodeType = "ORG" //叶子节点：某个组织的门限签名要求
+)
+
+// EndorsementPolicy 以布尔表达式描述业务对TxStd.Endorsements的背书要求，
+// 例如 "用户签名 AND (3个validator中任意2个签名)"。可通过NewPolicy()链式构建，
+// 结构本身可JSON编码，随ITx一起在wire上传输
+type EndorsementPolicy struct {
+	Type      PolicyNodeType       `json:"type"`
+	Children  []*EndorsementPolicy `json:"children,omitempty"`  //AND/OR节点的子节点
+	Name      string               `json:"name,omitempty"`      //ORG叶子节点：组织名，仅用于描述/日志
+	Threshold uint                 `json:"threshold,omitempty"` //ORG叶子节点：要求的有效签名数
+	PubKeys   []crypto.PubKey      `json:"pubkeys,omitempty"`   //ORG叶子节点：允许参与背书的公钥集合
+}
+
+// PolicyTx ITx可选实现此接口，返回该业务tx要求的背书策略；
+// 该方法返回的策略始终优先于TxStd.Policy——后者未被纳入任何SignMode的签名字节，
+// 不可信任其覆盖ITx声明的策略，仅在ITx未实现该接口或返回nil时作为兜底
+type PolicyTx interface {
+	GetEndorsementPolicy() *EndorsementPolicy
+}
+
+// NewPolicy 创建一个待定义的策略节点，需紧接着调用And/Or/Org之一确定节点类型
+func NewPolicy() *EndorsementPolicy {
+	return &EndorsementPolicy{}
+}
+
+// And 将p设置为AND节点，要求所有children均满足
+func (p *EndorsementPolicy) And(children ...*EndorsementPolicy) *EndorsementPolicy {
+	p.Type = PolicyAnd
+	p.Children = children
+	return p
+}
+
+// Or 将p设置为OR节点，要求至少一个children满足
+func (p *EndorsementPolicy) Or(children ...*EndorsementPolicy) *EndorsementPolicy {
+	p.Type = PolicyOr
+	p.Children = children
+	return p
+}
+
+// Org 将p设置为ORG叶子节点：要求pubKeys中至少threshold个公钥提供了有效背书签名
+func (p *EndorsementPolicy) Org(name string, threshold uint, pubKeys ...crypto.PubKey) *EndorsementPolicy {
+	p.Type = PolicyOrg
+	p.Name = name
+	p.Threshold = threshold
+	p.PubKeys = pubKeys
+	return p
+}
+
+// Satisfy 校验endorsements是否满足该策略描述的布尔表达式，
+// signData为各背书签名对应的原文（即ITx.GetSignData()）
+func (p *EndorsementPolicy) Satisfy(endorsements []Signature, signData []byte) bool {
+	if p == nil {
+		return true
+	}
+
+	switch p.Type {
+	case PolicyAnd:
+		if len(p.Children) == 0 {
+			return false
+		}
+		for _, child := range p.Children {
+			if !child.Satisfy(endorsements, signData) {
+				return false
+			}
+		}
+		return true
+	case PolicyOr:
+		for _, child := range p.Children {
+			if child.Satisfy(endorsements, signData) {
+				return true
+			}
+		}
+		return false
+	case PolicyOrg:
+		return countValidEndorsements(endorsements, signData, p.PubKeys) >= int(p.Threshold)
+	default:
+		return false
+	}
+}
+
+// countValidEndorsements 统计endorsements中来自allowed公钥集合、且对signData签名有效的
+// 不同背书人数量；同一公钥出现多次（重复提交或复制他人签名到多个条目）只记一次，
+// 避免单一背书人凑出"K个不同背书人"的门限
+func countValidEndorsements(endorsements []Signature, signData []byte, allowed []crypto.PubKey) int {
+	credited := make(map[string]bool)
+	for _, e := range endorsements {
+		if e.Pubkey == nil {
+			continue
+		}
+		key := string(e.Pubkey.Bytes())
+		if credited[key] {
+			continue
+		}
+		for _, pk := range allowed {
+			if pk.Equals(e.Pubkey) && pk.VerifyBytes(signData, e.Signature) {
+				credited[key] = true
+				break
+			}
+		}
+	}
+	return len(credited)
+}
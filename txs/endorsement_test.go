@@ -0,0 +1,74 @@
+package txs
+
+import (
+	"testing"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+func TestEndorsementPolicy_Satisfy(t *testing.T) {
+	userKey := ed25519.GenPrivKey()
+	val1, val2, val3 := ed25519.GenPrivKey(), ed25519.GenPrivKey(), ed25519.GenPrivKey()
+
+	msg := []byte("sign-data")
+
+	sig := func(pk ed25519.PrivKeyEd25519) Signature {
+		s, _ := pk.Sign(msg)
+		return Signature{Pubkey: pk.PubKey(), Signature: s}
+	}
+
+	policy := NewPolicy().And(
+		NewPolicy().Org("user", 1, userKey.PubKey()),
+		NewPolicy().Or(
+			NewPolicy().Org("validators", 2, val1.PubKey(), val2.PubKey(), val3.PubKey()),
+		),
+	)
+
+	// 仅user签名，缺少validator背书
+	if policy.Satisfy([]Signature{sig(userKey)}, msg) {
+		t.Fatalf("expected policy not satisfied with only user endorsement")
+	}
+
+	// user + 2个validator签名，满足策略
+	endorsements := []Signature{sig(userKey), sig(val1), sig(val2)}
+	if !policy.Satisfy(endorsements, msg) {
+		t.Fatalf("expected policy satisfied with user + 2 validator endorsements")
+	}
+
+	// 错误的签名数据不应被计入有效背书
+	wrongMsgSig, _ := val1.Sign([]byte("other-data"))
+	endorsements = []Signature{
+		sig(userKey),
+		{Pubkey: val1.PubKey(), Signature: wrongMsgSig},
+		sig(val2),
+	}
+	if policy.Satisfy(endorsements, msg) {
+		t.Fatalf("expected policy not satisfied when a validator signature is over the wrong data")
+	}
+}
+
+func TestEndorsementPolicy_DuplicateEndorsementNotDoubleCounted(t *testing.T) {
+	userKey := ed25519.GenPrivKey()
+	val1, val2, val3 := ed25519.GenPrivKey(), ed25519.GenPrivKey(), ed25519.GenPrivKey()
+
+	msg := []byte("sign-data")
+
+	sig := func(pk ed25519.PrivKeyEd25519) Signature {
+		s, _ := pk.Sign(msg)
+		return Signature{Pubkey: pk.PubKey(), Signature: s}
+	}
+
+	policy := NewPolicy().Org("validators", 2, val1.PubKey(), val2.PubKey(), val3.PubKey())
+
+	// 同一背书人的有效签名重复出现两次，不应被当作2个不同背书人计入门限
+	duplicated := []Signature{sig(val1), sig(val1)}
+	if policy.Satisfy(duplicated, msg) {
+		t.Fatalf("expected policy not satisfied when the same endorser's signature is duplicated instead of distinct")
+	}
+
+	// 2个不同背书人各提供一次有效签名，才满足门限
+	distinct := []Signature{sig(val1), sig(val2)}
+	if !policy.Satisfy(distinct, msg) {
+		t.Fatalf("expected policy satisfied with 2 distinct endorsers")
+	}
+}
@@ -0,0 +1,228 @@
+package txs
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+// CompactBitArray 定长位数组的紧凑表示，第i位置位表示某个k-of-n多签的第i个子公钥已提供签名，
+// 相比[]bool更节省编码体积
+type CompactBitArray struct {
+	ExtraBitsStored byte   `json:"extra_bits_stored"` //最后一个字节中实际使用的bit数，0表示整字节都被使用
+	Elems           []byte `json:"elems"`
+}
+
+// NewCompactBitArray 创建可容纳bits个bit位的CompactBitArray，初始均为0
+func NewCompactBitArray(bits int) *CompactBitArray {
+	if bits <= 0 {
+		return nil
+	}
+
+	return &CompactBitArray{
+		ExtraBitsStored: byte(bits % 8),
+		Elems:           make([]byte, (bits+7)/8),
+	}
+}
+
+// Count 返回该位数组的容量（bit位总数）
+func (bA *CompactBitArray) Count() int {
+	if bA == nil {
+		return 0
+	}
+	if bA.ExtraBitsStored == 0 {
+		return len(bA.Elems) * 8
+	}
+	return (len(bA.Elems)-1)*8 + int(bA.ExtraBitsStored)
+}
+
+// GetIndex 返回下标i处的bit是否被置位
+func (bA *CompactBitArray) GetIndex(i int) bool {
+	if bA == nil || i < 0 || i >= bA.Count() {
+		return false
+	}
+	return bA.Elems[i>>3]&(1<<uint(7-i%8)) > 0
+}
+
+// SetIndex 设置下标i处的bit值，返回是否设置成功（下标越界时失败）
+func (bA *CompactBitArray) SetIndex(i int, v bool) bool {
+	if bA == nil || i < 0 || i >= bA.Count() {
+		return false
+	}
+	if v {
+		bA.Elems[i>>3] |= 1 << uint(7-i%8)
+	} else {
+		bA.Elems[i>>3] &= ^(1 << uint(7-i%8))
+	}
+	return true
+}
+
+// NumTrueBitsBefore 返回下标区间[0, index)内被置位的bit数量，
+// 用于将某个子公钥的下标映射到MultiSignature.Sigs中的槽位；逐位遍历[0, index)，耗时与index成正比
+func (bA *CompactBitArray) NumTrueBitsBefore(index int) int {
+	if bA == nil {
+		return 0
+	}
+
+	numTrueValues := 0
+	for i := 0; i < index; i++ {
+		if bA.GetIndex(i) {
+			numTrueValues++
+		}
+	}
+	return numTrueValues
+}
+
+// MultiSignature k-of-n多签已提供的子签名集合：BitArray标记哪些子公钥已签名，
+// Sigs按位下标顺序仅保存已提供的子签名
+type MultiSignature struct {
+	BitArray *CompactBitArray `json:"bit_array"`
+	Sigs     [][]byte         `json:"sigs"`
+}
+
+// NewMultiSignature 创建可容纳n个子公钥签名状态的空MultiSignature
+func NewMultiSignature(n int) *MultiSignature {
+	return &MultiSignature{
+		BitArray: NewCompactBitArray(n),
+		Sigs:     make([][]byte, 0, n),
+	}
+}
+
+// AddSignature 将index对应子公钥的签名sig加入MultiSignature，
+// 若该位已存在签名则覆盖，否则按位顺序插入
+func (ms *MultiSignature) AddSignature(sig []byte, index int) {
+	newSigIndex := ms.BitArray.NumTrueBitsBefore(index)
+	if ms.BitArray.GetIndex(index) {
+		ms.Sigs[newSigIndex] = sig
+		return
+	}
+
+	ms.BitArray.SetIndex(index, true)
+	ms.Sigs = append(ms.Sigs, nil)
+	copy(ms.Sigs[newSigIndex+1:], ms.Sigs[newSigIndex:])
+	ms.Sigs[newSigIndex] = sig
+}
+
+// PubKeyMultisigThreshold k-of-n门限多签公钥：仅当至少K个PubKeys中的子公钥
+// 对同一数据提供有效签名时，聚合签名才被视为通过
+type PubKeyMultisigThreshold struct {
+	K       uint            `json:"threshold"`
+	PubKeys []crypto.PubKey `json:"pubkeys"`
+}
+
+var _ crypto.PubKey = PubKeyMultisigThreshold{}
+
+// NewPubKeyMultisigThreshold 构造k-of-n门限多签公钥
+func NewPubKeyMultisigThreshold(k uint, pubKeys []crypto.PubKey) PubKeyMultisigThreshold {
+	if k == 0 {
+		panic("PubKeyMultisigThreshold: k must be greater than 0")
+	}
+	if len(pubKeys) < int(k) {
+		panic("PubKeyMultisigThreshold: len(pubKeys) must not be less than k")
+	}
+
+	return PubKeyMultisigThreshold{K: k, PubKeys: pubKeys}
+}
+
+// Address 多签公钥对应的地址，由其规范编码哈希而来
+func (pk PubKeyMultisigThreshold) Address() crypto.Address {
+	return crypto.AddressHash(pk.Bytes())
+}
+
+// Bytes 多签公钥的规范编码
+func (pk PubKeyMultisigThreshold) Bytes() []byte {
+	bz, err := json.Marshal(pk)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// VerifyBytes 校验marshalledMultiSig（MultiSignature的JSON编码）中已提供的子签名是否均对msg有效，
+// 且有效子签名数量不少于K
+func (pk PubKeyMultisigThreshold) VerifyBytes(msg []byte, marshalledMultiSig []byte) bool {
+	var sig MultiSignature
+	if err := json.Unmarshal(marshalledMultiSig, &sig); err != nil {
+		return false
+	}
+
+	size := sig.BitArray.Count()
+	if size != len(pk.PubKeys) {
+		return false
+	}
+
+	trueBits := sig.BitArray.NumTrueBitsBefore(size)
+	if trueBits < int(pk.K) || trueBits != len(sig.Sigs) {
+		return false
+	}
+
+	sigIndex := 0
+	for i := 0; i < size; i++ {
+		if !sig.BitArray.GetIndex(i) {
+			continue
+		}
+		if !pk.PubKeys[i].VerifyBytes(msg, sig.Sigs[sigIndex]) {
+			return false
+		}
+		sigIndex++
+	}
+
+	return true
+}
+
+// Equals 比较两个多签公钥是否由相同门限和相同有序子公钥列表组成
+func (pk PubKeyMultisigThreshold) Equals(other crypto.PubKey) bool {
+	otherKey, ok := other.(PubKeyMultisigThreshold)
+	if !ok || pk.K != otherKey.K || len(pk.PubKeys) != len(otherKey.PubKeys) {
+		return false
+	}
+
+	for i := range pk.PubKeys {
+		if !pk.PubKeys[i].Equals(otherKey.PubKeys[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// AddMultiSignature 为tx追加或合并一条k-of-n多签条目：
+// 在pubKey.PubKeys中找到subPubKey的下标，将subSig记入对应的MultiSignature。
+// 若tx.Signature中尚无该pubKey对应的条目则新建一条，否则合并进已有条目
+//
+// 约定：subSig必须是subPubKey对 DefaultSignModeHandler{}.GetSignBytes(mode, signerData, tx)
+// 的签名，且该signerData.Signer必须填充为多签组地址pubKey.Address()，而非subPubKey自身的地址——
+// 验证时（ValidateBasicData）使用的正是以pubKey.Address()作为Signer构造出的签名字节。
+// 这意味着SignModeLegacyAmino/SignModeTextual下，各子签名者需被告知
+// 使用组地址而非自己的地址来签名；调用方在收集子签名时需确保这一点。
+// SignModeDirect/SignModeSortedJSON的签名字节不包含signerData.Signer
+func (tx *TxStd) AddMultiSignature(nonce int64, mode SignMode, pubKey PubKeyMultisigThreshold, subSig []byte, subPubKey crypto.PubKey) error {
+	index := -1
+	for i, pk := range pubKey.PubKeys {
+		if pk.Equals(subPubKey) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return errors.New("AddMultiSignature err(subPubKey not found in pubKey.PubKeys)")
+	}
+
+	for i := range tx.Signature {
+		if tx.Signature[i].Pubkey != nil && tx.Signature[i].Pubkey.Equals(pubKey) {
+			tx.Signature[i].MultiSignature.AddSignature(subSig, index)
+			return nil
+		}
+	}
+
+	ms := NewMultiSignature(len(pubKey.PubKeys))
+	ms.AddSignature(subSig, index)
+	tx.Signature = append(tx.Signature, Signature{
+		Pubkey:         pubKey,
+		Nonce:          nonce,
+		Mode:           mode,
+		MultiSignature: ms,
+	})
+
+	return nil
+}
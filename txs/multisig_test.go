@@ -0,0 +1,147 @@
+package txs
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/QOSGroup/qbase/types"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+func TestCompactBitArray_SetGetIndex(t *testing.T) {
+	bA := NewCompactBitArray(5)
+
+	if bA.Count() != 5 {
+		t.Fatalf("expected capacity 5, got %d", bA.Count())
+	}
+
+	if !bA.SetIndex(1, true) || !bA.SetIndex(3, true) {
+		t.Fatalf("expected SetIndex to succeed within bounds")
+	}
+
+	for i := 0; i < 5; i++ {
+		want := i == 1 || i == 3
+		if bA.GetIndex(i) != want {
+			t.Fatalf("GetIndex(%d) = %v, want %v", i, bA.GetIndex(i), want)
+		}
+	}
+
+	if bA.SetIndex(5, true) {
+		t.Fatalf("expected SetIndex out of bounds to fail")
+	}
+}
+
+func TestCompactBitArray_NumTrueBitsBefore(t *testing.T) {
+	bA := NewCompactBitArray(8)
+	bA.SetIndex(1, true)
+	bA.SetIndex(4, true)
+	bA.SetIndex(6, true)
+
+	cases := map[int]int{0: 0, 1: 0, 2: 1, 4: 1, 5: 2, 6: 2, 7: 3, 8: 3}
+	for index, want := range cases {
+		if got := bA.NumTrueBitsBefore(index); got != want {
+			t.Fatalf("NumTrueBitsBefore(%d) = %d, want %d", index, got, want)
+		}
+	}
+}
+
+func TestMultiSignature_AddSignatureOutOfOrder(t *testing.T) {
+	ms := NewMultiSignature(3)
+
+	ms.AddSignature([]byte("sig-for-2"), 2)
+	ms.AddSignature([]byte("sig-for-0"), 0)
+
+	if len(ms.Sigs) != 2 {
+		t.Fatalf("expected 2 sigs recorded, got %d", len(ms.Sigs))
+	}
+	if string(ms.Sigs[0]) != "sig-for-0" || string(ms.Sigs[1]) != "sig-for-2" {
+		t.Fatalf("expected sigs in bit-index order, got %q", ms.Sigs)
+	}
+
+	// 覆盖已存在的位
+	ms.AddSignature([]byte("sig-for-0-updated"), 0)
+	if len(ms.Sigs) != 2 || string(ms.Sigs[0]) != "sig-for-0-updated" {
+		t.Fatalf("expected existing index to be overwritten in place, got %q", ms.Sigs)
+	}
+}
+
+func TestSig2Byte_IncludesMultiSignature(t *testing.T) {
+	pubKey := ed25519.GenPrivKey().PubKey()
+
+	withoutMultiSig := Signature{Pubkey: pubKey, Nonce: 1}
+
+	ms := NewMultiSignature(2)
+	ms.AddSignature([]byte("sub-sig"), 0)
+	withMultiSig := Signature{Pubkey: pubKey, Nonce: 1, MultiSignature: ms}
+
+	if bytes.Equal(Sig2Byte(withoutMultiSig), Sig2Byte(withMultiSig)) {
+		t.Fatalf("expected Sig2Byte to reflect MultiSignature content")
+	}
+}
+
+// TestMultiSignature_RoundTripsThroughNonDirectSignMode 验证AddMultiSignature文档中约定的契约：
+// 在SignModeLegacyAmino下，子签名者必须对signerData.Signer = 多签组地址(而非自己的地址)的
+// 签名字节签名，这样聚合后的MultiSignature才能通过ValidateBasicData使用的同一套签名字节校验
+func TestMultiSignature_RoundTripsThroughNonDirectSignMode(t *testing.T) {
+	priv1, priv2, priv3 := ed25519.GenPrivKey(), ed25519.GenPrivKey(), ed25519.GenPrivKey()
+	multiPubKey := NewPubKeyMultisigThreshold(2, []crypto.PubKey{priv1.PubKey(), priv2.PubKey(), priv3.PubKey()})
+
+	itx := &basicFixtureTx{Name: "alice", Tags: []string{"a"}}
+	tx := NewTxStd(itx, "test-chain", types.NewInt(10))
+
+	const mode = SignModeLegacyAmino
+	const nonce = int64(1)
+
+	groupSignerData := SignerData{
+		ChainID:      tx.ChainID,
+		AccountNonce: nonce,
+		Signer:       types.Address(multiPubKey.Address()),
+	}
+	signBytes, err := DefaultSignModeHandler{}.GetSignBytes(mode, groupSignerData, tx)
+	if err != nil {
+		t.Fatalf("GetSignBytes error: %s", err)
+	}
+
+	sig1, err := priv1.Sign(signBytes)
+	if err != nil {
+		t.Fatalf("priv1.Sign error: %s", err)
+	}
+	sig3, err := priv3.Sign(signBytes)
+	if err != nil {
+		t.Fatalf("priv3.Sign error: %s", err)
+	}
+
+	if err := tx.AddMultiSignature(nonce, mode, multiPubKey, sig1, priv1.PubKey()); err != nil {
+		t.Fatalf("AddMultiSignature(priv1) error: %s", err)
+	}
+	if err := tx.AddMultiSignature(nonce, mode, multiPubKey, sig3, priv3.PubKey()); err != nil {
+		t.Fatalf("AddMultiSignature(priv3) error: %s", err)
+	}
+
+	if len(tx.Signature) != 1 {
+		t.Fatalf("expected a single merged multisig entry, got %d", len(tx.Signature))
+	}
+	entry := tx.Signature[0]
+
+	// 复现ValidateBasicData中多签校验所用的signerData/signBytes构造方式
+	verifySignerData := SignerData{
+		ChainID:      tx.ChainID,
+		AccountNonce: entry.Nonce,
+		Signer:       types.Address(entry.Pubkey.Address()),
+	}
+	verifySignBytes, err := DefaultSignModeHandler{}.GetSignBytes(entry.Mode, verifySignerData, tx)
+	if err != nil {
+		t.Fatalf("GetSignBytes (verify) error: %s", err)
+	}
+
+	multiSigBytes, err := json.Marshal(entry.MultiSignature)
+	if err != nil {
+		t.Fatalf("marshal MultiSignature error: %s", err)
+	}
+
+	if !multiPubKey.VerifyBytes(verifySignBytes, multiSigBytes) {
+		t.Fatalf("expected multisig to verify through SignModeLegacyAmino")
+	}
+}
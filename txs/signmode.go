@@ -0,0 +1,127 @@
+package txs
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/QOSGroup/qbase/types"
+	"github.com/pkg/errors"
+)
+
+// SignMode 标识签名字节的编码方式，不同钱包/客户端可按需选择
+type SignMode int32
+
+const (
+	// SignModeDirect 对ITx的二进制编码直接签名，拼接方式见directSignBytes
+	SignModeDirect SignMode = iota
+	// SignModeLegacyAmino 对ITx的Amino风格JSON编码签名，兼容旧版钱包
+	SignModeLegacyAmino
+	// SignModeTextual 对人类可读的文本渲染签名，便于硬件钱包等设备展示签名内容
+	SignModeTextual
+	// SignModeSortedJSON 对key按ASCII顺序排序、省略空值的规范化JSON签名，供非Go客户端复现签名字节
+	SignModeSortedJSON
+)
+
+// SignerData 描述某一签名者在签名时所需的上下文信息
+// 不会修改TxStd本身，每个签名者各自持有一份
+type SignerData struct {
+	ChainID      string        //执行链ID
+	AccountNonce int64         //该签名者的nonce
+	Signer       types.Address //签名者地址；多签场景下须为多签组地址(PubKeyMultisigThreshold.Address())，而非各子签名者自己的地址，见AddMultiSignature
+}
+
+// SignModeHandler 根据SignMode为TxStd生成签名字节
+type SignModeHandler interface {
+	Modes() []SignMode
+	GetSignBytes(mode SignMode, signerData SignerData, tx *TxStd) ([]byte, error)
+}
+
+// TextualSigner ITx可选实现此接口，自定义SignModeTextual下展示给用户的文本内容
+type TextualSigner interface {
+	GetSignTextual() string
+}
+
+// DefaultSignModeHandler 默认的SignModeHandler实现，支持Direct/LegacyAmino/Textual/SortedJSON四种模式
+type DefaultSignModeHandler struct{}
+
+var _ SignModeHandler = DefaultSignModeHandler{}
+
+// Modes 返回该handler支持的所有SignMode
+func (DefaultSignModeHandler) Modes() []SignMode {
+	return []SignMode{SignModeDirect, SignModeLegacyAmino, SignModeTextual, SignModeSortedJSON}
+}
+
+// GetSignBytes 按mode分发到具体的编码实现
+func (DefaultSignModeHandler) GetSignBytes(mode SignMode, signerData SignerData, tx *TxStd) ([]byte, error) {
+	if tx.ITx == nil {
+		return nil, errors.New("GetSignBytes err(itx is nil)")
+	}
+
+	switch mode {
+	case SignModeDirect:
+		return directSignBytes(signerData, tx), nil
+	case SignModeLegacyAmino:
+		return aminoJSONSignBytes(signerData, tx)
+	case SignModeTextual:
+		return textualSignBytes(signerData, tx), nil
+	case SignModeSortedJSON:
+		return sortedJSONSignBytes(signerData, tx)
+	default:
+		return nil, errors.Errorf("unrecognized SignMode: %d", mode)
+	}
+}
+
+// directSignBytes 按 ITx字节 ++ ChainID ++ MaxGas ++ NotBeforeHeight ++ NotAfterHeight ++ Timestamp ++ Nonce 拼接，
+// 即TxStd.GetSignData()之后追加nonce；相比历史版本多了三个有效期字段
+func directSignBytes(signerData SignerData, tx *TxStd) []byte {
+	ret := tx.GetSignData()
+	ret = append(ret, types.Int2Byte(signerData.AccountNonce)...)
+
+	return ret
+}
+
+// aminoJSONSignDoc 描述LEGACY_AMINO_JSON模式下参与签名的字段
+type aminoJSONSignDoc struct {
+	ChainID         string        `json:"chain_id"`
+	Signer          types.Address `json:"signer,omitempty"`
+	MaxGas          string        `json:"max_gas"`
+	Nonce           int64         `json:"nonce"`
+	NotBeforeHeight int64         `json:"not_before_height,omitempty"`
+	NotAfterHeight  int64         `json:"not_after_height,omitempty"`
+	Timestamp       int64         `json:"timestamp,omitempty"`
+	Tx              ITx           `json:"tx"`
+}
+
+// aminoJSONSignBytes 生成规范的Amino风格JSON签名字节，便于钱包展示与跨语言复现。
+// signerData.Signer被一并纳入签名字节，使同一笔tx由不同签名者签出的字节各不相同
+func aminoJSONSignBytes(signerData SignerData, tx *TxStd) ([]byte, error) {
+	doc := aminoJSONSignDoc{
+		ChainID:         signerData.ChainID,
+		Signer:          signerData.Signer,
+		MaxGas:          tx.MaxGas.String(),
+		Nonce:           signerData.AccountNonce,
+		NotBeforeHeight: tx.NotBeforeHeight,
+		NotAfterHeight:  tx.NotAfterHeight,
+		Timestamp:       tx.Timestamp,
+		Tx:              tx.ITx,
+	}
+
+	return json.Marshal(doc)
+}
+
+// textualSignBytes 生成人类可读的签名文本，ITx可实现TextualSigner自定义展示内容。
+// signerData.Signer被渲染进文本首行，便于硬件钱包等设备向用户确认"正以哪个地址签名"
+func textualSignBytes(signerData SignerData, tx *TxStd) []byte {
+	var body string
+	if ts, ok := tx.ITx.(TextualSigner); ok {
+		body = ts.GetSignTextual()
+	} else {
+		body = fmt.Sprintf("%+v", tx.ITx)
+	}
+
+	text := fmt.Sprintf("signer: %s\nchain-id: %s\nnonce: %d\nmax-gas: %s\nnot-before-height: %d\nnot-after-height: %d\ntimestamp: %d\n%s",
+		signerData.Signer, signerData.ChainID, signerData.AccountNonce, tx.MaxGas.String(),
+		tx.NotBeforeHeight, tx.NotAfterHeight, tx.Timestamp, body)
+
+	return []byte(text)
+}
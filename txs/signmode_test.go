@@ -0,0 +1,61 @@
+package txs
+
+import (
+	"testing"
+
+	"github.com/QOSGroup/qbase/types"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+var allSignModes = []SignMode{SignModeDirect, SignModeLegacyAmino, SignModeTextual, SignModeSortedJSON}
+
+func TestSignTx_RoundTripPerMode(t *testing.T) {
+	for _, mode := range allSignModes {
+		priv := ed25519.GenPrivKey()
+		itx := &basicFixtureTx{Name: "alice", Tags: []string{"a"}}
+		tx := NewTxStd(itx, "test-chain", types.NewInt(10))
+
+		sig, err := tx.SignTx(priv, 1, mode)
+		if err != nil {
+			t.Fatalf("mode %d: SignTx error: %s", mode, err)
+		}
+
+		signerData := SignerData{
+			ChainID:      tx.ChainID,
+			AccountNonce: 1,
+			Signer:       types.Address(priv.PubKey().Address()),
+		}
+		signBytes, err := DefaultSignModeHandler{}.GetSignBytes(mode, signerData, tx)
+		if err != nil {
+			t.Fatalf("mode %d: GetSignBytes error: %s", mode, err)
+		}
+
+		if !priv.PubKey().VerifyBytes(signBytes, sig) {
+			t.Fatalf("mode %d: signature produced by SignTx failed to verify", mode)
+		}
+
+		// 换一个不相关的公钥不应通过校验
+		other := ed25519.GenPrivKey()
+		if other.PubKey().VerifyBytes(signBytes, sig) {
+			t.Fatalf("mode %d: signature verified against an unrelated pubkey", mode)
+		}
+	}
+}
+
+func TestSignModes_ProduceDistinctSignBytes(t *testing.T) {
+	itx := &basicFixtureTx{Name: "alice", Tags: []string{"a"}}
+	tx := NewTxStd(itx, "test-chain", types.NewInt(10))
+	signerData := SignerData{ChainID: tx.ChainID, AccountNonce: 1}
+
+	seenBy := make(map[string]SignMode, len(allSignModes))
+	for _, mode := range allSignModes {
+		bz, err := DefaultSignModeHandler{}.GetSignBytes(mode, signerData, tx)
+		if err != nil {
+			t.Fatalf("mode %d: GetSignBytes error: %s", mode, err)
+		}
+		if other, ok := seenBy[string(bz)]; ok {
+			t.Fatalf("mode %d produced sign bytes identical to mode %d", mode, other)
+		}
+		seenBy[string(bz)] = mode
+	}
+}
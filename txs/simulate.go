@@ -0,0 +1,75 @@
+package txs
+
+import (
+	"github.com/QOSGroup/qbase/context"
+	"github.com/QOSGroup/qbase/types"
+	"github.com/pkg/errors"
+)
+
+// SimulationSigner 模拟执行下使用的哨兵签名者地址。
+// 若ITx.GetSigner()的实现需要一个真实的调用者身份，可在TxStd.SimulateAndExecute为true时
+// 使用该地址代替真实签名者，使未签名的tx也能被正常模拟
+var SimulationSigner = types.Address("simulation-signer")
+
+// SimulationAware ITx可选实现此接口，在Simulate()执行前接收一个占位的调用者身份(SimulationSigner)，
+// 使GetSigner()依赖真实身份的业务逻辑也能在未签名状态下被模拟执行
+type SimulationAware interface {
+	SetSimulationSigner(signer types.Address)
+}
+
+// SimulateResult TxStd.Simulate的模拟执行结果，仅用于预估gas消耗与预览执行效果，不影响真实状态
+type SimulateResult struct {
+	GasUsed    types.BigInt  //ITx.CalcGas()计算出的gas消耗
+	Result     types.Result  //ITx.Exec()的执行结果
+	CrossTxQcp *TxQcp        //ITx.Exec()产生的跨链TxQcp
+	Events     []types.Event //执行过程中产生的事件
+}
+
+// Simulate 在一次性的CacheContext中执行ITx.ValidateData与ITx.Exec，用于预估gas消耗与预览执行效果。
+// 跳过签名校验，未签名的tx也可被模拟；CacheContext保证不会对真实的multistore产生任何写入。
+// 执行期间tx.SimulateAndExecute被置为true：若tx.ITx实现了SimulationAware，会收到SimulationSigner
+// 作为占位的调用者身份，供依赖真实身份的GetSigner()等实现使用。该字段是瞬态的，Simulate返回前
+// 会恢复为调用前的值，因此对一个之后仍要真实签名/提交的tx调用Simulate不会使其永久跳过签名校验
+func (tx *TxStd) Simulate(ctx context.Context) (SimulateResult, error) {
+	if tx.ITx == nil {
+		return SimulateResult{}, errors.New("Simulate err(itx is nil)")
+	}
+
+	prevSimulateAndExecute := tx.SimulateAndExecute
+	tx.SimulateAndExecute = true
+	defer func() { tx.SimulateAndExecute = prevSimulateAndExecute }()
+
+	if sa, ok := tx.ITx.(SimulationAware); ok {
+		sa.SetSimulationSigner(SimulationSigner)
+	}
+
+	simCtx, _ := ctx.CacheContext()
+
+	if err := tx.ITx.ValidateData(simCtx); err != nil {
+		return SimulateResult{}, err
+	}
+
+	result, crossTxQcp := tx.ITx.Exec(simCtx)
+
+	return SimulateResult{
+		GasUsed:    tx.ITx.CalcGas(),
+		Result:     result,
+		CrossTxQcp: crossTxQcp,
+		Events:     result.Events,
+	}, nil
+}
+
+// EstimateGas 通过模拟执行itx估算所需gas，并按gasAdjustment系数放大，得到可安全用作MaxGas的值，
+// 类似Cosmos Factory.simulateAndExecute之后对gas estimate做adjustment的用法
+func EstimateGas(ctx context.Context, itx ITx, gasAdjustment float64) (types.BigInt, error) {
+	tx := NewTxStd(itx, "", types.ZeroInt())
+	tx.SimulateAndExecute = true
+
+	simResult, err := tx.Simulate(ctx)
+	if err != nil {
+		return types.ZeroInt(), err
+	}
+
+	adjusted := int64(float64(simResult.GasUsed.Int64()) * gasAdjustment)
+	return types.NewInt(adjusted), nil
+}
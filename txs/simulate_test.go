@@ -0,0 +1,70 @@
+package txs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/QOSGroup/qbase/context"
+	"github.com/QOSGroup/qbase/types"
+)
+
+// simAwareFixtureTx 的GetSigner()依赖一个真实的调用者身份；未设置身份前返回空签名者列表，
+// 用于验证Simulate()会在执行前通过SimulationAware注入SimulationSigner
+type simAwareFixtureTx struct {
+	signer types.Address
+}
+
+func (tx *simAwareFixtureTx) SetSimulationSigner(signer types.Address) { tx.signer = signer }
+
+func (tx *simAwareFixtureTx) ValidateData(ctx context.Context) error { return nil }
+func (tx *simAwareFixtureTx) Exec(ctx context.Context) (result types.Result, crossTxQcp *TxQcp) {
+	return
+}
+func (tx *simAwareFixtureTx) GetSigner() []types.Address {
+	if tx.signer == nil {
+		return nil
+	}
+	return []types.Address{tx.signer}
+}
+func (tx *simAwareFixtureTx) CalcGas() types.BigInt      { return types.NewInt(5) }
+func (tx *simAwareFixtureTx) GetGasPayer() types.Address { return tx.signer }
+func (tx *simAwareFixtureTx) GetSignData() []byte        { return []byte("sim-aware") }
+
+var _ ITx = (*simAwareFixtureTx)(nil)
+var _ SimulationAware = (*simAwareFixtureTx)(nil)
+
+func TestSimulate_InjectsSimulationSigner(t *testing.T) {
+	fx := &simAwareFixtureTx{}
+
+	if signers := fx.GetSigner(); len(signers) != 0 {
+		t.Fatalf("expected no signer before simulation, got %v", signers)
+	}
+
+	tx := NewTxStd(fx, "test-chain", types.NewInt(100))
+	if _, err := tx.Simulate(context.Context{}); err != nil {
+		t.Fatalf("Simulate returned error: %s", err)
+	}
+
+	if tx.SimulateAndExecute {
+		t.Fatalf("expected Simulate to restore SimulateAndExecute to its pre-call value once it returns")
+	}
+
+	signers := fx.GetSigner()
+	if len(signers) != 1 || !bytes.Equal(signers[0], SimulationSigner) {
+		t.Fatalf("expected GetSigner to return SimulationSigner after Simulate, got %v", signers)
+	}
+}
+
+func TestSimulate_RestoresPriorSimulateAndExecuteValue(t *testing.T) {
+	fx := &simAwareFixtureTx{}
+	tx := NewTxStd(fx, "test-chain", types.NewInt(100))
+	tx.SimulateAndExecute = true
+
+	if _, err := tx.Simulate(context.Context{}); err != nil {
+		t.Fatalf("Simulate returned error: %s", err)
+	}
+
+	if !tx.SimulateAndExecute {
+		t.Fatalf("expected Simulate to restore SimulateAndExecute to its pre-call value (true), got false")
+	}
+}
@@ -0,0 +1,223 @@
+package txs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// marshalerType 用于识别实现了json.Marshaler的叶子类型（如types.BigInt、crypto.PubKey等），
+// 这些类型不再展开其内部字段，而是直接复用其自身的JSON编码结果
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// CanonicalJSON 将ITx编码为规范化JSON，供SignModeSortedJSON及其它语言的客户端复现签名字节。
+// 规则：
+//   - 每一层级的key均按ASCII顺序排序；
+//   - 打了 `sign:"-"` 标签的字段被忽略；
+//   - 打了 `json:"-"` 标签的字段被忽略；
+//   - 零值/空值字段（0、""、nil、长度为0的slice/map）被省略。
+func CanonicalJSON(itx ITx) ([]byte, error) {
+	v, _, err := canonicalValue(reflect.ValueOf(itx))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+// canonicalValue 递归地将v转换为仅由map[string]interface{}/[]interface{}/基本类型组成的规范化结构，
+// 第二个返回值标识该value是否为"空"（供调用方决定是否在上一层省略该字段）
+func canonicalValue(v reflect.Value) (interface{}, bool, error) {
+	if !v.IsValid() {
+		return nil, true, nil
+	}
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, true, nil
+		}
+		v = v.Elem()
+	}
+
+	if marshaler, ok := asJSONMarshaler(v); ok {
+		raw, err := marshaler.MarshalJSON()
+		if err != nil {
+			return nil, false, err
+		}
+
+		var leaf interface{}
+		if err := json.Unmarshal(raw, &leaf); err != nil {
+			return nil, false, err
+		}
+
+		// types.BigInt等类型的零值会编码为"0"这类非空字符串，需借助其自身的零值判断
+		empty := isEmptyLeaf(leaf)
+		if !empty {
+			if reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface()) {
+				empty = true
+			} else if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+				empty = z.IsZero()
+			}
+		}
+
+		return leaf, empty, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return canonicalStruct(v)
+	case reflect.Map:
+		if v.Len() == 0 {
+			return map[string]interface{}{}, true, nil
+		}
+		obj := make(map[string]interface{}, v.Len())
+		for _, key := range v.MapKeys() {
+			val, _, err := canonicalValue(v.MapIndex(key))
+			if err != nil {
+				return nil, false, err
+			}
+			obj[fmt.Sprintf("%v", key.Interface())] = val
+		}
+		return obj, false, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, true, nil
+		}
+		if v.Len() == 0 {
+			return []interface{}{}, true, nil
+		}
+		arr := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			val, _, err := canonicalValue(v.Index(i))
+			if err != nil {
+				return nil, false, err
+			}
+			arr[i] = val
+		}
+		return arr, false, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Interface(), v.Int() == 0, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Interface(), v.Uint() == 0, nil
+	case reflect.Float32, reflect.Float64:
+		return v.Interface(), v.Float() == 0, nil
+	case reflect.Bool:
+		return v.Interface(), !v.Bool(), nil
+	case reflect.String:
+		return v.Interface(), v.String() == "", nil
+	default:
+		return v.Interface(), false, nil
+	}
+}
+
+// asJSONMarshaler 判断v（或其可取地址的指针形式）是否实现了json.Marshaler，
+// 用于识别types.BigInt、crypto地址等不应被展开为字段的叶子类型
+func asJSONMarshaler(v reflect.Value) (json.Marshaler, bool) {
+	if v.Type().Implements(marshalerType) {
+		return v.Interface().(json.Marshaler), true
+	}
+	if v.CanAddr() && reflect.PtrTo(v.Type()).Implements(marshalerType) {
+		return v.Addr().Interface().(json.Marshaler), true
+	}
+	return nil, false
+}
+
+// canonicalStruct 按字段的json/sign tag展开struct，省略被标记或空值的字段
+func canonicalStruct(v reflect.Value) (interface{}, bool, error) {
+	t := v.Type()
+	obj := make(map[string]interface{})
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" { // 未导出字段
+			continue
+		}
+
+		if tag, ok := field.Tag.Lookup("sign"); ok && tag == "-" {
+			continue
+		}
+
+		name, omit := jsonFieldName(field)
+		if omit {
+			continue
+		}
+
+		val, empty, err := canonicalValue(v.Field(i))
+		if err != nil {
+			return nil, false, err
+		}
+		if empty {
+			continue
+		}
+
+		obj[name] = val
+	}
+
+	return obj, len(obj) == 0, nil
+}
+
+// jsonFieldName 解析字段的json tag，返回规范化后使用的key；omit为true表示该字段应被整体忽略
+func jsonFieldName(field reflect.StructField) (name string, omit bool) {
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		return field.Name, false
+	}
+
+	parts := strings.Split(tag, ",")
+	if parts[0] == "-" {
+		return "", true
+	}
+	if parts[0] != "" {
+		return parts[0], false
+	}
+
+	return field.Name, false
+}
+
+// isEmptyLeaf 判断一个已解析为interface{}的JSON叶子值是否为"空值"，用于大整数、地址等Marshaler类型
+func isEmptyLeaf(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case float64:
+		return t == 0
+	case bool:
+		return !t
+	default:
+		return false
+	}
+}
+
+// sortedJSONSignBytes 生成SignModeSortedJSON下实际参与签名的最终JSON文档
+func sortedJSONSignBytes(signerData SignerData, tx *TxStd) ([]byte, error) {
+	canonical, err := CanonicalJSON(tx.ITx)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(canonical, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+
+	doc["chain_id"] = signerData.ChainID
+	doc["max_gas"] = tx.MaxGas.String()
+	doc["nonce"] = signerData.AccountNonce
+	if tx.NotBeforeHeight != 0 {
+		doc["not_before_height"] = tx.NotBeforeHeight
+	}
+	if tx.NotAfterHeight != 0 {
+		doc["not_after_height"] = tx.NotAfterHeight
+	}
+	if tx.Timestamp != 0 {
+		doc["timestamp"] = tx.Timestamp
+	}
+
+	return json.Marshal(doc)
+}
@@ -0,0 +1,144 @@
+package txs
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/QOSGroup/qbase/context"
+	"github.com/QOSGroup/qbase/types"
+)
+
+type canonicalFixtureNested struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// basicFixtureTx 覆盖嵌套struct/map/slice以及sign/json忽略标签与空值省略规则，
+// 不涉及types.BigInt/types.Address的具体编码格式
+type basicFixtureTx struct {
+	Name   string                 `json:"name"`
+	Nested canonicalFixtureNested `json:"nested"`
+	Tags   []string               `json:"tags"`
+	Meta   map[string]string      `json:"meta"`
+	Secret string                 `json:"secret" sign:"-"`
+	Hidden string                 `json:"-"`
+	Empty  string                 `json:"empty"`
+}
+
+func (tx *basicFixtureTx) ValidateData(ctx context.Context) error { return nil }
+func (tx *basicFixtureTx) Exec(ctx context.Context) (result types.Result, crossTxQcp *TxQcp) {
+	return
+}
+func (tx *basicFixtureTx) GetSigner() []types.Address { return nil }
+func (tx *basicFixtureTx) CalcGas() types.BigInt      { return types.ZeroInt() }
+func (tx *basicFixtureTx) GetGasPayer() types.Address { return nil }
+func (tx *basicFixtureTx) GetSignData() []byte        { return []byte(tx.Name) }
+
+var _ ITx = (*basicFixtureTx)(nil)
+
+func TestCanonicalJSON_Golden(t *testing.T) {
+	fx := &basicFixtureTx{
+		Name:   "alice",
+		Nested: canonicalFixtureNested{Label: "n1", Count: 3},
+		Tags:   []string{"a", "b"},
+		Meta:   map[string]string{"z": "1", "a": "2"},
+		Secret: "should-not-appear",
+		Hidden: "also-should-not-appear",
+		Empty:  "",
+	}
+
+	got, err := CanonicalJSON(fx)
+	if err != nil {
+		t.Fatalf("CanonicalJSON returned error: %s", err)
+	}
+
+	want, err := ioutil.ReadFile("testdata/canonical_basic.golden.json")
+	if err != nil {
+		t.Fatalf("read golden file: %s", err)
+	}
+
+	if strings.TrimSpace(string(got)) != strings.TrimSpace(string(want)) {
+		t.Fatalf("CanonicalJSON mismatch.\n got: %s\nwant: %s", got, want)
+	}
+
+	if strings.Contains(string(got), "should-not-appear") {
+		t.Fatalf("field tagged sign:\"-\" leaked into canonical JSON: %s", got)
+	}
+}
+
+// bigFixtureTx 覆盖types.BigInt与types.Address字段的省略/保留规则
+type bigFixtureTx struct {
+	Amount   types.BigInt  `json:"amount"`
+	ZeroAmt  types.BigInt  `json:"zero_amt"`
+	Receiver types.Address `json:"receiver"`
+}
+
+func (tx *bigFixtureTx) ValidateData(ctx context.Context) error { return nil }
+func (tx *bigFixtureTx) Exec(ctx context.Context) (result types.Result, crossTxQcp *TxQcp) {
+	return
+}
+func (tx *bigFixtureTx) GetSigner() []types.Address { return nil }
+func (tx *bigFixtureTx) CalcGas() types.BigInt      { return types.ZeroInt() }
+func (tx *bigFixtureTx) GetGasPayer() types.Address { return tx.Receiver }
+func (tx *bigFixtureTx) GetSignData() []byte        { return nil }
+
+var _ ITx = (*bigFixtureTx)(nil)
+
+func TestCanonicalJSON_BigIntAndAddress(t *testing.T) {
+	fx := &bigFixtureTx{
+		Amount:   types.NewInt(100),
+		ZeroAmt:  types.ZeroInt(),
+		Receiver: types.Address([]byte{0xAB, 0xCD}),
+	}
+
+	got, err := CanonicalJSON(fx)
+	if err != nil {
+		t.Fatalf("CanonicalJSON returned error: %s", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(got, &doc); err != nil {
+		t.Fatalf("CanonicalJSON produced invalid JSON: %s", err)
+	}
+
+	if _, ok := doc["amount"]; !ok {
+		t.Fatalf("expected non-zero BigInt field 'amount' to be present: %s", got)
+	}
+	if _, ok := doc["zero_amt"]; ok {
+		t.Fatalf("expected zero-valued BigInt field 'zero_amt' to be omitted: %s", got)
+	}
+	if _, ok := doc["receiver"]; !ok {
+		t.Fatalf("expected non-empty Address field 'receiver' to be present: %s", got)
+	}
+}
+
+func TestSortedJSONSignBytes_AppendsSignerData(t *testing.T) {
+	itx := &basicFixtureTx{Name: "bob", Tags: []string{"x"}}
+	tx := NewTxStd(itx, "test-chain", types.NewInt(10))
+
+	signerData := SignerData{ChainID: "test-chain", AccountNonce: 7}
+	got, err := sortedJSONSignBytes(signerData, tx)
+	if err != nil {
+		t.Fatalf("sortedJSONSignBytes returned error: %s", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(got, &doc); err != nil {
+		t.Fatalf("sortedJSONSignBytes produced invalid JSON: %s", err)
+	}
+
+	if doc["chain_id"] != "test-chain" {
+		t.Fatalf("expected chain_id to be appended, got: %s", got)
+	}
+	if doc["max_gas"] != "10" {
+		t.Fatalf("expected max_gas to be appended, got: %s", got)
+	}
+	if doc["nonce"].(float64) != 7 {
+		t.Fatalf("expected nonce to be appended, got: %s", got)
+	}
+	if doc["name"] != "bob" {
+		t.Fatalf("expected itx fields to be present in sorted sign bytes, got: %s", got)
+	}
+}
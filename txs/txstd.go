@@ -1,6 +1,7 @@
 package txs
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/QOSGroup/qbase/context"
@@ -25,19 +26,40 @@ type ITx interface {
 
 // 标准Tx结构体
 type TxStd struct {
-	ITx       ITx          `json:"itx"`      //ITx接口，将被具体Tx结构实例化
-	Signature []Signature  `json:"sigature"` //签名数组
-	ChainID   string       `json:"chainid"`  //ChainID: 执行ITx.exec方法的链ID
-	MaxGas    types.BigInt `json:"maxgas"`   //Gas消耗的最大值
+	ITx          ITx                `json:"itx"`                    //ITx接口，将被具体Tx结构实例化
+	Signature    []Signature        `json:"sigature"`               //签名数组
+	ChainID      string             `json:"chainid"`                //ChainID: 执行ITx.exec方法的链ID
+	MaxGas       types.BigInt       `json:"maxgas"`                 //Gas消耗的最大值
+	Endorsements []Signature        `json:"endorsements,omitempty"` //背书签名数组，由Policy校验
+	Policy       *EndorsementPolicy `json:"policy,omitempty"`       //背书策略；仅在ITx未实现PolicyTx或其返回nil时生效
+
+	NotBeforeHeight int64 `json:"not_before_height,omitempty"` //tx生效的最小区块高度，0表示不限制
+	NotAfterHeight  int64 `json:"not_after_height,omitempty"`  //tx生效的最大区块高度，0表示不限制
+	Timestamp       int64 `json:"timestamp,omitempty"`         //tx构造时间(unix秒)，与出块时间的偏差超过MaxClockSkewSeconds将被拒绝，0表示不校验
+
+	SimulateAndExecute bool `json:"-"` //是否为gas估算/预执行请求，瞬态字段，不签名也不随wire编码持久化
 }
 
 var _ types.Tx = (*TxStd)(nil)
 
+// MaxClockSkewSeconds TxStd.Timestamp与出块时间(ctx.BlockHeader().Time)允许的最大偏差，
+// 业务链可通过SetMaxClockSkewSeconds调整
+var MaxClockSkewSeconds int64 = 10 * 60
+
+// SetMaxClockSkewSeconds 设置TxStd.Timestamp允许的最大时钟偏移(秒)
+func SetMaxClockSkewSeconds(seconds int64) {
+	MaxClockSkewSeconds = seconds
+}
+
 // 签名结构体
+// 普通签名场景下填充Pubkey/Signature；k-of-n多签场景下Pubkey为PubKeyMultisigThreshold，
+// 子签名记录在MultiSignature中，Signature字段不再使用
 type Signature struct {
-	Pubkey    crypto.PubKey `json:"pubkey"`    //可选
-	Signature []byte        `json:"signature"` //签名内容
-	Nonce     int64         `json:"nonce"`     //nonce的值
+	Pubkey         crypto.PubKey   `json:"pubkey"`                    //可选
+	Signature      []byte          `json:"signature"`                 //签名内容
+	Nonce          int64           `json:"nonce"`                     //nonce的值
+	Mode           SignMode        `json:"mode"`                      //签名内容的编码方式，详见SignMode
+	MultiSignature *MultiSignature `json:"multi_signature,omitempty"` //k-of-n多签时填充
 }
 
 // Type: just for implements types.Tx
@@ -55,17 +77,30 @@ func (tx *TxStd) GetSignData() []byte {
 	ret := tx.ITx.GetSignData()
 	ret = append(ret, []byte(tx.ChainID)...)
 	ret = append(ret, types.Int2Byte(tx.MaxGas.Int64())...)
+	ret = append(ret, types.Int2Byte(tx.NotBeforeHeight)...)
+	ret = append(ret, types.Int2Byte(tx.NotAfterHeight)...)
+	ret = append(ret, types.Int2Byte(tx.Timestamp)...)
 
 	return ret
 }
 
-// 签名：每个签名者外部调用此方法
-func (tx *TxStd) SignTx(privkey crypto.PrivKey, nonce int64) (signedbyte []byte, err error) {
+// 签名：每个签名者外部调用此方法，mode决定签名数据的编码方式，详见SignMode
+func (tx *TxStd) SignTx(privkey crypto.PrivKey, nonce int64, mode SignMode) (signedbyte []byte, err error) {
 	if tx.ITx == nil {
 		return nil, errors.New("Signature txstd err(itx is nil)")
 	}
 
-	sigdata := append(tx.GetSignData(), types.Int2Byte(nonce)...)
+	signerData := SignerData{
+		ChainID:      tx.ChainID,
+		AccountNonce: nonce,
+		Signer:       types.Address(privkey.PubKey().Address()),
+	}
+
+	sigdata, err := DefaultSignModeHandler{}.GetSignBytes(mode, signerData, tx)
+	if err != nil {
+		return nil, err
+	}
+
 	signedbyte, err = privkey.Sign(sigdata)
 	if err != nil {
 		return nil, err
@@ -82,11 +117,27 @@ func NewTxStd(itx ITx, cid string, mgas types.BigInt) (rTx *TxStd) {
 		[]Signature{},
 		cid,
 		mgas,
+		nil,
+		nil,
+		0,
+		0,
+		0,
+		false,
 	}
 
 	return
 }
 
+// NewTxStdWithTTL 构建带有效期窗口的TxStd：notBefore/notAfter为0表示对应方向不限制，
+// 区块高度超出[notBefore, notAfter]区间时TxStd.ValidateBasicData将拒绝该tx
+func NewTxStdWithTTL(itx ITx, cid string, mgas types.BigInt, notBefore, notAfter int64) (rTx *TxStd) {
+	rTx = NewTxStd(itx, cid, mgas)
+	rTx.NotBeforeHeight = notBefore
+	rTx.NotAfterHeight = notAfter
+
+	return
+}
+
 // 函数：Signature结构转化为 []byte
 func Sig2Byte(sgn Signature) (ret []byte) {
 	if sgn.Pubkey == nil {
@@ -95,12 +146,41 @@ func Sig2Byte(sgn Signature) (ret []byte) {
 	ret = append(ret, sgn.Pubkey.Bytes()...)
 	ret = append(ret, sgn.Signature...)
 	ret = append(ret, types.Int2Byte(sgn.Nonce)...)
+	ret = append(ret, types.Int2Byte(int64(sgn.Mode))...)
+	if sgn.MultiSignature != nil {
+		if bz, err := json.Marshal(sgn.MultiSignature); err == nil {
+			ret = append(ret, bz...)
+		}
+	}
 
 	return
 }
 
-//ValidateBasicData  对txStd进行基础的数据校验
-//tx.ITx == QcpTxResult时 不校验签名相关信息
+// validateTTL 校验给定的区块高度、出块时间是否落在tx的有效期窗口与允许的时钟偏移范围内。
+// 抽出为独立方法以便脱离context.Context直接测试
+func (tx *TxStd) validateTTL(blockHeight int64, blockTime int64) types.Error {
+	if tx.NotBeforeHeight != 0 && blockHeight < tx.NotBeforeHeight {
+		return types.ErrInternal(fmt.Sprintf("TxStd is not yet valid. current height: %d , NotBeforeHeight: %d", blockHeight, tx.NotBeforeHeight))
+	}
+	if tx.NotAfterHeight != 0 && blockHeight > tx.NotAfterHeight {
+		return types.ErrInternal(fmt.Sprintf("TxStd is expired. current height: %d , NotAfterHeight: %d", blockHeight, tx.NotAfterHeight))
+	}
+
+	if tx.Timestamp != 0 {
+		skew := blockTime - tx.Timestamp
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > MaxClockSkewSeconds {
+			return types.ErrInternal(fmt.Sprintf("TxStd's Timestamp exceeds max clock skew. timestamp: %d , block time: %d , max skew: %ds", tx.Timestamp, blockTime, MaxClockSkewSeconds))
+		}
+	}
+
+	return nil
+}
+
+// ValidateBasicData 对txStd进行基础的数据校验
+// tx.ITx == QcpTxResult时 不校验签名相关信息
 func (tx *TxStd) ValidateBasicData(ctx context.Context, isCheckTx bool, currentChaindID string) (err types.Error) {
 	if tx.ITx == nil {
 		return types.ErrInternal("TxStd's ITx is nil")
@@ -130,6 +210,10 @@ func (tx *TxStd) ValidateBasicData(ctx context.Context, isCheckTx bool, currentC
 		return types.ErrInternal(fmt.Sprintf("TxStd's MaxGas is less than itx exec gas. expect: %s , actual: %s", tx.MaxGas, execGas))
 	}
 
+	if err := tx.validateTTL(ctx.BlockHeight(), ctx.BlockHeader().Time.Unix()); err != nil {
+		return err
+	}
+
 	_, ok := tx.ITx.(*QcpTxResult)
 	if !ok {
 
@@ -144,8 +228,63 @@ func (tx *TxStd) ValidateBasicData(ctx context.Context, isCheckTx bool, currentC
 		}
 
 		if len(sigs) != len(singers) {
+			if tx.SimulateAndExecute {
+				return
+			}
 			return types.ErrUnauthorized(fmt.Sprintf("signatures and signers not match. signatures count: %d , singers count: %d ", len(sigs), len(singers)))
 		}
+
+		if tx.SimulateAndExecute {
+			return
+		}
+
+		handler := DefaultSignModeHandler{}
+		for _, sig := range sigs {
+			signerData := SignerData{
+				ChainID:      tx.ChainID,
+				AccountNonce: sig.Nonce,
+			}
+			if sig.Pubkey != nil {
+				signerData.Signer = types.Address(sig.Pubkey.Address())
+			}
+
+			signBytes, sigErr := handler.GetSignBytes(sig.Mode, signerData, tx)
+			if sigErr != nil {
+				return types.ErrUnauthorized(sigErr.Error())
+			}
+
+			if sig.Pubkey == nil {
+				continue
+			}
+
+			if sig.MultiSignature != nil {
+				multiSigBytes, jsonErr := json.Marshal(sig.MultiSignature)
+				if jsonErr != nil {
+					return types.ErrUnauthorized(jsonErr.Error())
+				}
+				if !sig.Pubkey.VerifyBytes(signBytes, multiSigBytes) {
+					return types.ErrUnauthorized(fmt.Sprintf("multisig verification failed, signer: %s", sig.Pubkey.Address()))
+				}
+				continue
+			}
+
+			if !sig.Pubkey.VerifyBytes(signBytes, sig.Signature) {
+				return types.ErrUnauthorized(fmt.Sprintf("signature verification failed, signer: %s", sig.Pubkey.Address()))
+			}
+		}
+	}
+
+	// tx.Policy不参与任何SignMode的签名字节计算，不可信任其覆盖ITx声明的策略：
+	// ITx若实现PolicyTx，其返回的策略始终优先；tx.Policy仅在ITx未声明策略时作为兜底
+	var policy *EndorsementPolicy
+	if policyTx, ok := tx.ITx.(PolicyTx); ok {
+		policy = policyTx.GetEndorsementPolicy()
+	}
+	if policy == nil {
+		policy = tx.Policy
+	}
+	if policy != nil && !policy.Satisfy(tx.Endorsements, tx.ITx.GetSignData()) {
+		return types.ErrUnauthorized("endorsements do not satisfy TxStd's EndorsementPolicy")
 	}
 
 	return
@@ -0,0 +1,79 @@
+package txs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/QOSGroup/qbase/types"
+)
+
+func TestNewTxStdWithTTL(t *testing.T) {
+	itx := &basicFixtureTx{Name: "alice"}
+	tx := NewTxStdWithTTL(itx, "test-chain", types.NewInt(10), 100, 200)
+
+	if tx.NotBeforeHeight != 100 || tx.NotAfterHeight != 200 {
+		t.Fatalf("expected NotBeforeHeight=100, NotAfterHeight=200, got %d, %d", tx.NotBeforeHeight, tx.NotAfterHeight)
+	}
+}
+
+func TestGetSignData_ChangesWithTTLFields(t *testing.T) {
+	itx := &basicFixtureTx{Name: "alice"}
+	base := NewTxStd(itx, "test-chain", types.NewInt(10))
+	withTTL := NewTxStdWithTTL(itx, "test-chain", types.NewInt(10), 100, 200)
+
+	if bytes.Equal(base.GetSignData(), withTTL.GetSignData()) {
+		t.Fatalf("expected GetSignData to differ once NotBeforeHeight/NotAfterHeight are set")
+	}
+}
+
+func TestValidateTTL_HeightWindow(t *testing.T) {
+	itx := &basicFixtureTx{Name: "alice"}
+	tx := NewTxStdWithTTL(itx, "test-chain", types.NewInt(10), 100, 200)
+
+	cases := []struct {
+		name        string
+		blockHeight int64
+		wantErr     bool
+	}{
+		{"below NotBeforeHeight", 99, true},
+		{"at NotBeforeHeight", 100, false},
+		{"within window", 150, false},
+		{"at NotAfterHeight", 200, false},
+		{"above NotAfterHeight", 201, true},
+	}
+
+	for _, c := range cases {
+		err := tx.validateTTL(c.blockHeight, 0)
+		if c.wantErr && err == nil {
+			t.Fatalf("%s: expected error, got nil", c.name)
+		}
+		if !c.wantErr && err != nil {
+			t.Fatalf("%s: expected no error, got %s", c.name, err.Error())
+		}
+	}
+}
+
+func TestValidateTTL_UnboundedWhenZero(t *testing.T) {
+	itx := &basicFixtureTx{Name: "alice"}
+	tx := NewTxStd(itx, "test-chain", types.NewInt(10))
+
+	if err := tx.validateTTL(1, 0); err != nil {
+		t.Fatalf("expected no height restriction when NotBeforeHeight/NotAfterHeight are zero, got %s", err.Error())
+	}
+}
+
+func TestValidateTTL_ClockSkew(t *testing.T) {
+	itx := &basicFixtureTx{Name: "alice"}
+	tx := NewTxStd(itx, "test-chain", types.NewInt(10))
+	tx.Timestamp = 1000
+
+	if err := tx.validateTTL(0, 1000+MaxClockSkewSeconds); err != nil {
+		t.Fatalf("expected skew at the boundary to be accepted, got %s", err.Error())
+	}
+	if err := tx.validateTTL(0, 1000-MaxClockSkewSeconds); err != nil {
+		t.Fatalf("expected negative skew at the boundary to be accepted, got %s", err.Error())
+	}
+	if err := tx.validateTTL(0, 1000+MaxClockSkewSeconds+1); err == nil {
+		t.Fatalf("expected error when skew exceeds MaxClockSkewSeconds")
+	}
+}